@@ -0,0 +1,150 @@
+package rpcfuzz
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultVolatileFields lists JSON object keys that legitimately
+// differ between two otherwise RPC-compatible nodes (different chain
+// ids, miners, clocks, gas markets, and block heights) and so are
+// stripped before diffing a test's result against a --reference-url
+// endpoint's response.
+var defaultVolatileFields = []string{
+	"chainId", "coinbase", "miner", "timestamp", "gasPrice", "gasUsed",
+	"baseFeePerGas", "number", "hash", "blockHash", "blockNumber",
+	"parentHash", "logsBloom", "nonce", "mixHash", "extraData",
+	"totalDifficulty", "difficulty", "size", "transactionIndex",
+}
+
+// NormalizeDropFields returns a Normalize function that recursively
+// strips the given JSON object keys from a decoded RPC result. It's
+// registered as the Normalize implementation for any RPCTest whose
+// result is a node-specific object (blocks, transactions, traces)
+// rather than a simple scalar.
+func NormalizeDropFields(fields ...string) func(interface{}) interface{} {
+	drop := toSet(fields)
+	var strip func(v interface{}) interface{}
+	strip = func(v interface{}) interface{} {
+		switch val := v.(type) {
+		case map[string]interface{}:
+			cleaned := make(map[string]interface{}, len(val))
+			for k, nested := range val {
+				if drop[k] {
+					continue
+				}
+				cleaned[k] = strip(nested)
+			}
+			return cleaned
+		case []interface{}:
+			cleaned := make([]interface{}, len(val))
+			for i, nested := range val {
+				cleaned[i] = strip(nested)
+			}
+			return cleaned
+		default:
+			return v
+		}
+	}
+	return strip
+}
+
+// diffNormalized structurally compares two already-normalized results
+// (rather than comparing raw JSON text, so key ordering doesn't cause
+// false positives) and returns a human readable description of every
+// difference found, plus whether any were found at all.
+func diffNormalized(primary, reference interface{}) (string, bool) {
+	diffs := make([]string, 0)
+	compareValues("$", primary, reference, &diffs)
+	if len(diffs) == 0 {
+		return "", false
+	}
+	return strings.Join(diffs, "; "), true
+}
+
+// diffAgainstReference re-runs t's call against referenceClient using
+// the same method and args the primary call just used, normalizes
+// both sides, and returns a description of any structural difference.
+// Errors talking to the reference node are logged and treated as "no
+// diff" rather than failing the primary test, except for a test that
+// expects an error, where the reference node's error is what gets
+// compared.
+func diffAgainstReference(cxt context.Context, referenceClient *rpc.Client, t RPCTest, primaryResult interface{}) string {
+	var referenceResult interface{}
+	err := referenceClient.CallContext(cxt, &referenceResult, t.GetMethod(), t.GetArgs()...)
+	if err != nil {
+		if !t.ExpectError() {
+			log.Warn().Err(err).Str("method", t.GetMethod()).Msg("Unable to call reference node for comparison")
+			return ""
+		}
+		referenceResult = err
+	}
+
+	diff, isDifferent := diffNormalized(t.Normalize(primaryResult), t.Normalize(referenceResult))
+	if !isDifferent {
+		return ""
+	}
+	return diff
+}
+
+func compareValues(path string, a, b interface{}, diffs *[]string) {
+	if a == nil || b == nil {
+		if a != nil || b != nil {
+			*diffs = append(*diffs, fmt.Sprintf("%s: %v != %v", path, a, b))
+		}
+		return
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		*diffs = append(*diffs, fmt.Sprintf("%s: type mismatch %T vs %T", path, a, b))
+		return
+	}
+
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv := b.(map[string]interface{})
+		keys := make(map[string]bool, len(av)+len(bv))
+		for k := range av {
+			keys[k] = true
+		}
+		for k := range bv {
+			keys[k] = true
+		}
+		sortedKeys := make([]string, 0, len(keys))
+		for k := range keys {
+			sortedKeys = append(sortedKeys, k)
+		}
+		sort.Strings(sortedKeys)
+
+		for _, k := range sortedKeys {
+			av2, aok := av[k]
+			bv2, bok := bv[k]
+			switch {
+			case aok && !bok:
+				*diffs = append(*diffs, fmt.Sprintf("%s.%s: only present in primary", path, k))
+			case !aok && bok:
+				*diffs = append(*diffs, fmt.Sprintf("%s.%s: only present in reference", path, k))
+			default:
+				compareValues(path+"."+k, av2, bv2, diffs)
+			}
+		}
+	case []interface{}:
+		bv := b.([]interface{})
+		if len(av) != len(bv) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: length mismatch %d vs %d", path, len(av), len(bv)))
+			return
+		}
+		for i := range av {
+			compareValues(fmt.Sprintf("%s[%d]", path, i), av[i], bv[i], diffs)
+		}
+	default:
+		if !reflect.DeepEqual(a, b) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: %v != %v", path, a, b))
+		}
+	}
+}
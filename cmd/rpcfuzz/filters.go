@@ -0,0 +1,173 @@
+package rpcfuzz
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/maticnetwork/polygon-cli/rpctypes"
+	"github.com/rs/zerolog/log"
+)
+
+// filterState holds the filter ids created by the filter-creation
+// tests below so that the dependent tests (getFilterChanges,
+// getFilterLogs, uninstallFilter) can reference the same filter
+// instead of each creating their own.
+var filterState struct {
+	logFilterID     string
+	blockFilterID   string
+	pendingFilterID string
+}
+
+type (
+	// RPCTestFilterCreate calls one of the eth_new*Filter methods and,
+	// once validated, stashes the returned filter id into the
+	// filterState field pointed to by store so later tests can use it.
+	RPCTestFilterCreate struct {
+		Method    string
+		Args      []interface{}
+		Validator func(result interface{}) error
+		store     *string
+	}
+
+	// RPCTestFilterDependent covers the filter methods that take a
+	// previously created filter id as their argument
+	// (eth_getFilterChanges, eth_getFilterLogs, eth_uninstallFilter).
+	RPCTestFilterDependent struct {
+		Method    string
+		FilterID  *string
+		Validator func(result interface{}) error
+	}
+)
+
+func (r *RPCTestFilterCreate) GetMethod() string {
+	return r.Method
+}
+func (r *RPCTestFilterCreate) GetArgs() []interface{} {
+	return r.Args
+}
+func (r *RPCTestFilterCreate) Validate(result interface{}) error {
+	if err := r.Validator(result); err != nil {
+		return err
+	}
+	resultStr, isValid := result.(string)
+	if !isValid {
+		return fmt.Errorf("Invalid result type. Expected string but got %T", result)
+	}
+	*r.store = resultStr
+	return nil
+}
+func (r *RPCTestFilterCreate) ExpectError() bool {
+	return false
+}
+func (r *RPCTestFilterCreate) GetNamespace() string {
+	return namespaceFromMethod(r.Method)
+}
+func (r *RPCTestFilterCreate) Normalize(result interface{}) interface{} {
+	// Filter ids are assigned by each node independently, so they'll
+	// never match across two endpoints; there's nothing meaningful to
+	// normalize here.
+	return result
+}
+func (r *RPCTestFilterCreate) GetArgTypes() []ArgKind {
+	return nil
+}
+func (r *RPCTestFilterCreate) Setup(rpcClient *rpc.Client) error {
+	return nil
+}
+func (r *RPCTestFilterCreate) Teardown(rpcClient *rpc.Client) error {
+	return nil
+}
+
+func (r *RPCTestFilterDependent) GetMethod() string {
+	return r.Method
+}
+func (r *RPCTestFilterDependent) GetArgs() []interface{} {
+	return []interface{}{*r.FilterID}
+}
+func (r *RPCTestFilterDependent) Validate(result interface{}) error {
+	return r.Validator(result)
+}
+func (r *RPCTestFilterDependent) ExpectError() bool {
+	return false
+}
+func (r *RPCTestFilterDependent) GetNamespace() string {
+	return namespaceFromMethod(r.Method)
+}
+func (r *RPCTestFilterDependent) Normalize(result interface{}) interface{} {
+	return NormalizeDropFields(defaultVolatileFields...)(result)
+}
+func (r *RPCTestFilterDependent) GetArgTypes() []ArgKind {
+	return nil
+}
+func (r *RPCTestFilterDependent) Setup(rpcClient *rpc.Client) error {
+	if *r.FilterID == "" {
+		return fmt.Errorf("No filter id available; the filter-creation test must run first")
+	}
+	return nil
+}
+func (r *RPCTestFilterDependent) Teardown(rpcClient *rpc.Client) error {
+	return nil
+}
+
+// setupFilterTests registers the eth_newFilter/eth_newBlockFilter/
+// eth_newPendingTransactionFilter family along with the dependent
+// getFilterChanges/getFilterLogs/uninstallFilter tests, plus a
+// standalone eth_getLogs test.
+func setupFilterTests() {
+	log.Trace().Msg("Registering filter tests")
+
+	// cast rpc --rpc-url localhost:8545 eth_newFilter '{"fromBlock":"earliest","toBlock":"latest"}'
+	logFilterCreate := RPCTestFilterCreate{
+		Method:    "eth_newFilter",
+		Args:      []interface{}{map[string]interface{}{"fromBlock": "earliest", "toBlock": "latest"}},
+		Validator: ValidateRegexString(`^0x[[:xdigit:]]{1,}$`),
+		store:     &filterState.logFilterID,
+	}
+	allTests = append(allTests, &logFilterCreate)
+
+	// cast rpc --rpc-url localhost:8545 eth_newBlockFilter
+	blockFilterCreate := RPCTestFilterCreate{
+		Method:    "eth_newBlockFilter",
+		Args:      []interface{}{},
+		Validator: ValidateRegexString(`^0x[[:xdigit:]]{1,}$`),
+		store:     &filterState.blockFilterID,
+	}
+	allTests = append(allTests, &blockFilterCreate)
+
+	// cast rpc --rpc-url localhost:8545 eth_newPendingTransactionFilter
+	pendingFilterCreate := RPCTestFilterCreate{
+		Method:    "eth_newPendingTransactionFilter",
+		Args:      []interface{}{},
+		Validator: ValidateRegexString(`^0x[[:xdigit:]]{1,}$`),
+		store:     &filterState.pendingFilterID,
+	}
+	allTests = append(allTests, &pendingFilterCreate)
+
+	// cast rpc --rpc-url localhost:8545 eth_getFilterChanges 0x...
+	allTests = append(allTests, &RPCTestFilterDependent{
+		Method:    "eth_getFilterChanges",
+		FilterID:  &filterState.blockFilterID,
+		Validator: ValidateJSONSchema(rpctypes.RPCSchemaStringArray),
+	})
+
+	// cast rpc --rpc-url localhost:8545 eth_getFilterLogs 0x...
+	allTests = append(allTests, &RPCTestFilterDependent{
+		Method:    "eth_getFilterLogs",
+		FilterID:  &filterState.logFilterID,
+		Validator: ValidateJSONSchema(rpctypes.RPCSchemaLogArray),
+	})
+
+	// cast rpc --rpc-url localhost:8545 eth_getLogs '{"fromBlock":"earliest","toBlock":"latest"}'
+	allTests = append(allTests, &RPCTestGeneric{
+		Method:    "eth_getLogs",
+		Args:      []interface{}{map[string]interface{}{"fromBlock": "earliest", "toBlock": "latest"}},
+		Validator: ValidateJSONSchema(rpctypes.RPCSchemaLogArray),
+	})
+
+	// cast rpc --rpc-url localhost:8545 eth_uninstallFilter 0x...
+	allTests = append(allTests, &RPCTestFilterDependent{
+		Method:    "eth_uninstallFilter",
+		FilterID:  &filterState.pendingFilterID,
+		Validator: ValidateExact(true),
+	})
+}
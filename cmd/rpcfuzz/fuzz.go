@@ -0,0 +1,208 @@
+package rpcfuzz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/rs/zerolog/log"
+)
+
+// ArgKind describes what kind of value an RPCTest argument is, so the
+// --fuzz mutator can pick a mutation strategy that's appropriate for
+// it (bit-flipping a hex string makes sense; bit-flipping a bool
+// doesn't).
+type ArgKind int
+
+const (
+	ArgKindOther ArgKind = iota
+	ArgKindHex
+	ArgKindBlockTag
+	ArgKindAddress
+	ArgKindBool
+)
+
+// panicShapedError matches the error text go-ethereum and most forks
+// emit when a request manages to reach a code path that panics or
+// otherwise misbehaves internally, as opposed to a well-formed
+// "invalid params" rejection.
+var panicShapedError = regexp.MustCompile(`(?i)runtime error|invalid memory|nil pointer|index out of range`)
+
+// serverErrorStatus matches the error text go-ethereum's http client
+// returns for a non-2xx response whose status is a 5xx.
+var serverErrorStatus = regexp.MustCompile(`^5\d\d\b`)
+
+// fuzzFinding is one mutated call that produced a 5xx or panic-shaped
+// error, recorded so the exact input can be replayed later.
+type fuzzFinding struct {
+	Method string        `json:"method"`
+	Args   []interface{} `json:"args"`
+	Error  string        `json:"error"`
+}
+
+// runFuzzMode mutates the arguments of every test that declares
+// GetArgTypes(), fires fuzzIterations mutated calls per test at
+// rpcClient, and writes any call that produces a 5xx or panic-shaped
+// error to corpusFile so it can be reproduced later.
+func runFuzzMode(cxt context.Context, rpcClient *rpc.Client, tests []RPCTest, seed int64, iterations int, corpusFile string) error {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	log.Info().Int64("seed", seed).Int("iterations", iterations).Msg("Starting fuzz mode")
+	rng := rand.New(rand.NewSource(seed))
+
+	findings := make([]fuzzFinding, 0)
+	for _, t := range tests {
+		argTypes := t.GetArgTypes()
+		if len(argTypes) == 0 {
+			continue
+		}
+
+		baseArgs := t.GetArgs()
+		for i := 0; i < iterations; i++ {
+			mutatedArgs := mutateArgs(rng, baseArgs, argTypes)
+
+			var result interface{}
+			err := rpcClient.CallContext(cxt, &result, t.GetMethod(), mutatedArgs...)
+			if err == nil {
+				continue
+			}
+			if !panicShapedError.MatchString(err.Error()) && !serverErrorStatus.MatchString(err.Error()) {
+				continue
+			}
+
+			log.Warn().Str("method", t.GetMethod()).Interface("args", mutatedArgs).Err(err).Msg("Fuzz finding")
+			findings = append(findings, fuzzFinding{
+				Method: t.GetMethod(),
+				Args:   mutatedArgs,
+				Error:  err.Error(),
+			})
+		}
+	}
+
+	log.Info().Int("findings", len(findings)).Msg("Fuzz mode complete")
+	return writeCorpus(corpusFile, findings)
+}
+
+func writeCorpus(corpusFile string, findings []fuzzFinding) error {
+	f, err := os.Create(corpusFile)
+	if err != nil {
+		return fmt.Errorf("Unable to create corpus file %s: %w", corpusFile, err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(findings)
+}
+
+// mutateArgs applies a type-aware mutation to exactly one argument
+// (chosen at random) of baseArgs, leaving the rest untouched. Mutating
+// a single argument per call keeps findings easy to attribute to a
+// specific bad input.
+func mutateArgs(rng *rand.Rand, baseArgs []interface{}, argTypes []ArgKind) []interface{} {
+	mutated := make([]interface{}, len(baseArgs))
+	copy(mutated, baseArgs)
+	if len(baseArgs) == 0 {
+		return mutated
+	}
+
+	target := rng.Intn(len(baseArgs))
+	if target >= len(argTypes) {
+		return mutated
+	}
+
+	switch argTypes[target] {
+	case ArgKindHex:
+		mutated[target] = mutateHex(rng, mutated[target])
+	case ArgKindBlockTag:
+		mutated[target] = mutateBlockTag(rng)
+	case ArgKindAddress:
+		mutated[target] = mutateAddress(rng, mutated[target])
+	case ArgKindBool:
+		mutated[target] = mutateBool(rng)
+	}
+	return mutated
+}
+
+func mutateHex(rng *rand.Rand, original interface{}) interface{} {
+	s, ok := original.(string)
+	if !ok {
+		s = "0x0"
+	}
+
+	switch rng.Intn(4) {
+	case 0: // bit-flip a random character
+		if len(s) < 3 {
+			return s
+		}
+		chars := []rune(s)
+		idx := 2 + rng.Intn(len(chars)-2)
+		chars[idx] = flipHexChar(chars[idx])
+		return string(chars)
+	case 1: // truncate
+		if len(s) < 4 {
+			return s
+		}
+		return s[:len(s)/2]
+	case 2: // strip the 0x prefix
+		return strings.TrimPrefix(s, "0x")
+	default: // inject an overlong payload
+		return s + strings.Repeat("ff", 1024)
+	}
+}
+
+func flipHexChar(c rune) rune {
+	digits := "0123456789abcdef"
+	idx := strings.IndexRune(digits, c)
+	if idx < 0 {
+		// c isn't a hex digit (shouldn't happen for a well-formed hex
+		// arg, but this mutator exists to survive malformed input) -
+		// flip it to some other digit instead of indexing out of range.
+		idx = 0
+	}
+	return rune(digits[15-idx])
+}
+
+func mutateBlockTag(rng *rand.Rand) interface{} {
+	tags := []interface{}{
+		"safe", "finalized", "0xffffffffffffffff", "-0x1", "notareal tag", 0,
+	}
+	return tags[rng.Intn(len(tags))]
+}
+
+func mutateAddress(rng *rand.Rand, original interface{}) interface{} {
+	s, ok := original.(string)
+	if !ok {
+		s = "0x0000000000000000000000000000000000000000"
+	}
+
+	switch rng.Intn(2) {
+	case 0: // corrupt length
+		if len(s) < 4 {
+			return s
+		}
+		return s[:len(s)-rng.Intn(len(s)-2)]
+	default: // corrupt checksum by flipping the case of every letter
+		chars := []rune(s)
+		for i, c := range chars {
+			if c >= 'a' && c <= 'f' {
+				chars[i] = c - 32
+			} else if c >= 'A' && c <= 'F' {
+				chars[i] = c + 32
+			}
+		}
+		return string(chars)
+	}
+}
+
+func mutateBool(rng *rand.Rand) interface{} {
+	options := []interface{}{nil, "true", "false", 1, "notabool"}
+	return options[rng.Intn(len(options))]
+}
@@ -0,0 +1,58 @@
+package rpcfuzz
+
+import (
+	"github.com/maticnetwork/polygon-cli/rpctypes"
+	"github.com/rs/zerolog/log"
+)
+
+// registerOpenRPCTests loads the OpenRPC document at source and
+// appends a baseline RPCTestGeneric for every method that isn't
+// already covered by a hand-written test in allTests, using the
+// method's first documented param example as its args and its result
+// schema for validation. Hand-written tests always win: this only
+// fills in the gaps, it never overrides an existing registration.
+//
+// Methods with a param lacking an example, or with no result schema, are skipped
+// rather than guessed at, since a wrong guess would just be a
+// confusing false failure.
+func registerOpenRPCTests(source string) {
+	if source == "" {
+		return
+	}
+
+	doc, err := rpctypes.LoadOpenRPCSpec(source)
+	if err != nil {
+		log.Error().Err(err).Str("source", source).Msg("Unable to load OpenRPC spec; skipping auto-registered tests")
+		return
+	}
+
+	covered := make(map[string]bool, len(allTests))
+	for _, t := range allTests {
+		covered[t.GetMethod()] = true
+	}
+
+	registered := 0
+	for _, m := range doc.Methods {
+		if covered[m.Name] {
+			continue
+		}
+
+		args, ok := m.ExampleParams()
+		if !ok {
+			continue
+		}
+		schema, ok := m.ResultSchema()
+		if !ok {
+			continue
+		}
+
+		allTests = append(allTests, &RPCTestGeneric{
+			Method:    m.Name,
+			Args:      args,
+			Validator: ValidateJSONSchema(schema),
+		})
+		registered++
+	}
+
+	log.Info().Int("registered", registered).Str("source", source).Msg("Auto-registered tests from OpenRPC spec")
+}
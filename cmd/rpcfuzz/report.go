@@ -0,0 +1,252 @@
+package rpcfuzz
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// namespaceFromMethod derives the RPC namespace (the part before the
+// first underscore) from a json rpc method name, e.g. "eth_chainId" ->
+// "eth". This mirrors how Ethereum RPC servers group methods into
+// namespaces like eth, net, web3, personal, txpool, debug, admin, and
+// miner.
+func namespaceFromMethod(method string) string {
+	idx := strings.Index(method, "_")
+	if idx < 0 {
+		return method
+	}
+	return method[:idx]
+}
+
+// selectTests filters tests down to the requested namespaces and
+// methods, then removes anything matched by exclude. A namespace or
+// method name in exclude is matched against both GetNamespace and
+// GetMethod so a single --exclude flag can drop either.
+func selectTests(tests []RPCTest, namespaces, methods, exclude []string) ([]RPCTest, error) {
+	namespaceSet := toSet(namespaces)
+	methodSet := toSet(methods)
+	excludeSet := toSet(exclude)
+
+	selected := make([]RPCTest, 0, len(tests))
+	for _, t := range tests {
+		if len(namespaceSet) > 0 && !namespaceSet[t.GetNamespace()] {
+			continue
+		}
+		if len(methodSet) > 0 && !methodSet[t.GetMethod()] {
+			continue
+		}
+		if excludeSet[t.GetNamespace()] || excludeSet[t.GetMethod()] {
+			continue
+		}
+		selected = append(selected, t)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("No tests remain after applying --namespaces/--methods/--exclude filters")
+	}
+	return selected, nil
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+type testStatus string
+
+const (
+	testStatusPass testStatus = "pass"
+	testStatusFail testStatus = "fail"
+	testStatusSkip testStatus = "skip"
+)
+
+// testRecord is a single test's outcome, kept around long enough to be
+// rendered into a report.
+type testRecord struct {
+	Namespace     string        `json:"namespace"`
+	Method        string        `json:"method"`
+	Status        testStatus    `json:"status"`
+	Duration      time.Duration `json:"durationNs"`
+	Error         string        `json:"error,omitempty"`
+	ReferenceDiff string        `json:"referenceDiff,omitempty"`
+}
+
+// testSuite accumulates testRecords as the runner executes tests, and
+// knows how to summarize itself per namespace for the report writers.
+type testSuite struct {
+	records []testRecord
+}
+
+func newTestSuite() *testSuite {
+	return &testSuite{records: make([]testRecord, 0)}
+}
+
+func (s *testSuite) record(t RPCTest, status testStatus, duration time.Duration, err error) {
+	s.recordWithDiff(t, status, duration, err, "")
+}
+
+func (s *testSuite) recordWithDiff(t RPCTest, status testStatus, duration time.Duration, err error, referenceDiff string) {
+	rec := testRecord{
+		Namespace:     t.GetNamespace(),
+		Method:        t.GetMethod(),
+		Status:        status,
+		Duration:      duration,
+		ReferenceDiff: referenceDiff,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	s.records = append(s.records, rec)
+}
+
+type namespaceSummary struct {
+	Namespace string        `json:"namespace"`
+	Pass      int           `json:"pass"`
+	Fail      int           `json:"fail"`
+	Skip      int           `json:"skip"`
+	Duration  time.Duration `json:"durationNs"`
+}
+
+func (s *testSuite) summarizeByNamespace() []namespaceSummary {
+	order := make([]string, 0)
+	byNamespace := make(map[string]*namespaceSummary)
+	for _, rec := range s.records {
+		summary, ok := byNamespace[rec.Namespace]
+		if !ok {
+			summary = &namespaceSummary{Namespace: rec.Namespace}
+			byNamespace[rec.Namespace] = summary
+			order = append(order, rec.Namespace)
+		}
+		switch rec.Status {
+		case testStatusPass:
+			summary.Pass++
+		case testStatusFail:
+			summary.Fail++
+		case testStatusSkip:
+			summary.Skip++
+		}
+		summary.Duration += rec.Duration
+	}
+
+	summaries := make([]namespaceSummary, 0, len(order))
+	for _, ns := range order {
+		summaries = append(summaries, *byNamespace[ns])
+	}
+	return summaries
+}
+
+// writeReport renders the suite in the requested format ("json" or
+// "junit") to reportFile, or to stdout if reportFile is empty.
+func writeReport(suite *testSuite, format, reportFile string) error {
+	out := os.Stdout
+	if reportFile != "" {
+		f, err := os.Create(reportFile)
+		if err != nil {
+			return fmt.Errorf("Unable to create report file %s: %w", reportFile, err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch format {
+	case "json":
+		return writeJSONReport(suite, out)
+	case "junit":
+		return writeJUnitReport(suite, out)
+	default:
+		return fmt.Errorf("Unknown report format %s, expected json or junit", format)
+	}
+}
+
+func writeJSONReport(suite *testSuite, out *os.File) error {
+	payload := struct {
+		Namespaces []namespaceSummary `json:"namespaces"`
+		Tests      []testRecord       `json:"tests"`
+	}{
+		Namespaces: suite.summarizeByNamespace(),
+		Tests:      suite.records,
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(payload)
+}
+
+// junitTestSuites/junitTestSuite/junitTestCase model just enough of the
+// JUnit XML schema for CI systems to render pass/fail/skip counts and
+// timing per namespace.
+type (
+	junitTestSuites struct {
+		XMLName xml.Name         `xml:"testsuites"`
+		Suites  []junitTestSuite `xml:"testsuite"`
+	}
+
+	junitTestSuite struct {
+		Name      string          `xml:"name,attr"`
+		Tests     int             `xml:"tests,attr"`
+		Failures  int             `xml:"failures,attr"`
+		Skipped   int             `xml:"skipped,attr"`
+		TimeSec   float64         `xml:"time,attr"`
+		TestCases []junitTestCase `xml:"testcase"`
+	}
+
+	junitTestCase struct {
+		Name      string       `xml:"name,attr"`
+		ClassName string       `xml:"classname,attr"`
+		TimeSec   float64      `xml:"time,attr"`
+		Failure   *junitResult `xml:"failure,omitempty"`
+		Skipped   *junitResult `xml:"skipped,omitempty"`
+	}
+
+	junitResult struct {
+		Message string `xml:"message,attr"`
+	}
+)
+
+func writeJUnitReport(suite *testSuite, out *os.File) error {
+	byNamespace := make(map[string]*junitTestSuite)
+	order := make([]string, 0)
+
+	for _, rec := range suite.records {
+		ts, ok := byNamespace[rec.Namespace]
+		if !ok {
+			ts = &junitTestSuite{Name: rec.Namespace}
+			byNamespace[rec.Namespace] = ts
+			order = append(order, rec.Namespace)
+		}
+
+		tc := junitTestCase{
+			Name:      rec.Method,
+			ClassName: rec.Namespace,
+			TimeSec:   rec.Duration.Seconds(),
+		}
+		switch rec.Status {
+		case testStatusFail:
+			tc.Failure = &junitResult{Message: rec.Error}
+			ts.Failures++
+		case testStatusSkip:
+			tc.Skipped = &junitResult{Message: rec.Error}
+			ts.Skipped++
+		}
+		ts.Tests++
+		ts.TimeSec += rec.Duration.Seconds()
+		ts.TestCases = append(ts.TestCases, tc)
+	}
+
+	doc := junitTestSuites{}
+	for _, ns := range order {
+		doc.Suites = append(doc.Suites, *byNamespace[ns])
+	}
+
+	if _, err := out.WriteString(xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
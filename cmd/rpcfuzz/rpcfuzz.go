@@ -7,6 +7,8 @@ import (
 	"encoding/json"
 	"fmt"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/maticnetwork/polygon-cli/rpctypes"
@@ -14,9 +16,11 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/xeipuuv/gojsonschema"
+	"math/big"
 	"os"
 	"regexp"
 	"strings"
+	"time"
 )
 
 type (
@@ -33,6 +37,34 @@ type (
 
 		// ExpectError is used by the validation code to understand of the test typically returns an error
 		ExpectError() bool
+
+		// GetNamespace returns the RPC namespace (e.g. "eth", "net",
+		// "web3") that this test's method belongs to, used to group
+		// and select tests.
+		GetNamespace() string
+
+		// Normalize strips or rewrites fields in result that are
+		// expected to legitimately differ between two otherwise
+		// compatible nodes (chain id, coinbase, timestamps, gas
+		// price, block number, ...) before --reference-url comparator
+		// mode diffs it against a second endpoint's response.
+		Normalize(result interface{}) interface{}
+
+		// GetArgTypes describes, positionally, what kind of value
+		// each of GetArgs' entries is (hex string, block tag,
+		// address, bool, ...) so that --fuzz mode knows what mutation
+		// strategy to apply to each argument. A nil/empty return means
+		// this test is skipped in --fuzz mode.
+		GetArgTypes() []ArgKind
+
+		// Setup runs before the test's RPC call is made. Tests that
+		// need to prime state (e.g. submitting a transaction that a
+		// later test references) do that here instead of in GetArgs.
+		Setup(rpcClient *rpc.Client) error
+
+		// Teardown runs after the test's result has been validated,
+		// regardless of whether validation succeeded.
+		Teardown(rpcClient *rpc.Client) error
 	}
 
 	// RPCTestGenric is the simplist implementation of the
@@ -40,13 +72,25 @@ type (
 	// managed by just returning hard coded values for method,
 	// args, validator, and error
 	RPCTestGeneric struct {
-		Method    string
-		Args      []interface{}
-		Validator func(result interface{}) error
-		IsError   bool
+		Method     string
+		Args       []interface{}
+		Validator  func(result interface{}) error
+		IsError    bool
+		Normalizer func(result interface{}) interface{}
+		ArgTypes   []ArgKind
 	}
 )
 
+// pendingTxState is shared by the pending-state test group so that
+// tests which observe a pending transaction (by hash, by block index,
+// etc.) can reference the nonce recorded by RPCTestPendingTx's Setup
+// and the hash recorded by its Validate without re-submitting a new
+// transaction of their own.
+var pendingTxState struct {
+	txHash ethcommon.Hash
+	nonce  uint64
+}
+
 const (
 	codeQualityPrivateKey = "42b6e34dc21598a807dc19d7784c71b2a7a01f6480dc6f58258f78e539f1a1fa"
 )
@@ -56,6 +100,24 @@ var (
 	testContractAddress *string
 	testPrivateKey      *ecdsa.PrivateKey
 	testEthAddress      ethcommon.Address
+
+	testNamespaces *[]string
+	testMethods    *[]string
+	testExclude    *[]string
+	reportFormat   *string
+	reportFile     *string
+	referenceURL   *string
+	fuzzEnabled    *bool
+	fuzzIterations *int
+	fuzzSeed       *int64
+	fuzzCorpusFile *string
+	openrpcSource  *string
+
+	// currentRPCURL is the endpoint RPCFuzzCmd was invoked with. It's
+	// used by tests (e.g. RPCTestSubscription) that need to know
+	// whether the transport supports server push before deciding to
+	// run or skip.
+	currentRPCURL string
 )
 
 var (
@@ -86,6 +148,13 @@ var (
 
 	RPCTestEthBlockByNumber RPCTestGeneric
 
+	RPCTestEthSendRawTransactionPending                  RPCTestPendingTx
+	RPCTestEthGetTransactionCountPending                 RPCTestPendingNonce
+	RPCTestEthGetBlockByNumberPending                    RPCTestPendingBlock
+	RPCTestEthGetBlockTransactionCountByNumberPending    RPCTestPendingBlock
+	RPCTestEthGetTransactionByHashPending                RPCTestPendingTxLookup
+	RPCTestEthGetTransactionByBlockNumberAndIndexPending RPCTestPendingTxLookup
+
 	allTests = make([]RPCTest, 0)
 )
 
@@ -111,6 +180,7 @@ func setupTests() {
 		Method:    "web3_sha3",
 		Args:      []interface{}{"0x68656c6c6f20776f726c64"},
 		Validator: ValidateRegexString(`0x47173285a8d7341e5e972fc677286384f802f8ef42a5ec5f03bbfa254cb01fad`),
+		ArgTypes:  []ArgKind{ArgKindHex},
 	}
 	allTests = append(allTests, &RPCTestWeb3SHA3)
 
@@ -222,6 +292,7 @@ func setupTests() {
 		Method:    "eth_getBalance",
 		Args:      []interface{}{testEthAddress.String(), "latest"},
 		Validator: ValidateRegexString(`^0x[[:xdigit:]]{1,}$`),
+		ArgTypes:  []ArgKind{ArgKindAddress, ArgKindBlockTag},
 	}
 	allTests = append(allTests, &RPCTestEthGetBalanceLatest)
 	RPCTestEthGetBalanceEarliest = RPCTestGeneric{
@@ -242,6 +313,7 @@ func setupTests() {
 		Method:    "eth_getStorageAt",
 		Args:      []interface{}{*testContractAddress, "0x3", "latest"},
 		Validator: ValidateRegexString(`^0x000000000000000000000000` + strings.ToLower(testEthAddress.String())[2:] + `$`),
+		ArgTypes:  []ArgKind{ArgKindAddress, ArgKindHex, ArgKindBlockTag},
 	}
 	allTests = append(allTests, &RPCTestEthGetStorageAtLatest)
 	RPCTestEthGetStorageAtEarliest = RPCTestGeneric{
@@ -262,6 +334,7 @@ func setupTests() {
 		Method:    "eth_getTransactionCount",
 		Args:      []interface{}{testEthAddress.String(), "latest"},
 		Validator: ValidateRegexString(`^0x[[:xdigit:]]{1,}$`),
+		ArgTypes:  []ArgKind{ArgKindAddress, ArgKindBlockTag},
 	}
 	allTests = append(allTests, &RPCTestEthGetTransactionCountAtLatest)
 	RPCTestEthGetTransactionCountAtEarliest = RPCTestGeneric{
@@ -288,9 +361,59 @@ func setupTests() {
 		Method:    "eth_getBlockByNumber",
 		Args:      []interface{}{"0x0", true},
 		Validator: ValidateJSONSchema(rpctypes.RPCSchemaEthBlock),
+		ArgTypes:  []ArgKind{ArgKindBlockTag, ArgKindBool},
 	}
 	allTests = append(allTests, &RPCTestEthBlockByNumber)
 
+	// Pending state test group. RPCTestEthSendRawTransactionPending
+	// signs a transaction and stashes its nonce in pendingTxState via
+	// its Setup hook, the runner's eth_sendRawTransaction call submits
+	// it, and its Validate stashes the returned hash; the rest of this group reads
+	// that state back out to make sure the "pending" tag actually
+	// reflects it rather than just matching a regex.
+	RPCTestEthSendRawTransactionPending = RPCTestPendingTx{
+		Validator: ValidateRegexString(`^0x[[:xdigit:]]{64}$`),
+	}
+	allTests = append(allTests, &RPCTestEthSendRawTransactionPending)
+
+	RPCTestEthGetTransactionCountPending = RPCTestPendingNonce{
+		Validator: ValidateRegexString(`^0x[[:xdigit:]]{1,}$`),
+	}
+	allTests = append(allTests, &RPCTestEthGetTransactionCountPending)
+
+	// cast rpc --rpc-url localhost:8545 eth_getBlockByNumber pending true
+	RPCTestEthGetBlockByNumberPending = RPCTestPendingBlock{
+		Method:    "eth_getBlockByNumber",
+		Validator: ValidateJSONSchema(rpctypes.RPCSchemaEthBlock),
+	}
+	allTests = append(allTests, &RPCTestEthGetBlockByNumberPending)
+
+	// cast rpc --rpc-url localhost:8545 eth_getBlockTransactionCountByNumber pending
+	RPCTestEthGetBlockTransactionCountByNumberPending = RPCTestPendingBlock{
+		Method:    "eth_getBlockTransactionCountByNumber",
+		Validator: ValidateRegexString(`^0x[[:xdigit:]]{1,}$`),
+	}
+	allTests = append(allTests, &RPCTestEthGetBlockTransactionCountByNumberPending)
+
+	// cast rpc --rpc-url localhost:8545 eth_getTransactionByHash 0x...
+	RPCTestEthGetTransactionByHashPending = RPCTestPendingTxLookup{
+		Method:    "eth_getTransactionByHash",
+		Validator: ValidateJSONSchema(rpctypes.RPCSchemaEthTransaction),
+	}
+	allTests = append(allTests, &RPCTestEthGetTransactionByHashPending)
+
+	// cast rpc --rpc-url localhost:8545 eth_getTransactionByBlockNumberAndIndex pending 0x0
+	RPCTestEthGetTransactionByBlockNumberAndIndexPending = RPCTestPendingTxLookup{
+		Method:    "eth_getTransactionByBlockNumberAndIndex",
+		Validator: ValidateJSONSchema(rpctypes.RPCSchemaEthTransaction),
+	}
+	allTests = append(allTests, &RPCTestEthGetTransactionByBlockNumberAndIndexPending)
+
+	setupFilterTests()
+	setupSubscriptionTests()
+	setupDebugTests()
+
+	registerOpenRPCTests(*openrpcSource)
 }
 
 // ChainValidator would take a list of validation functions to be
@@ -391,6 +514,349 @@ func (r *RPCTestGeneric) Validate(result interface{}) error {
 func (r *RPCTestGeneric) ExpectError() bool {
 	return r.IsError
 }
+func (r *RPCTestGeneric) GetNamespace() string {
+	return namespaceFromMethod(r.Method)
+}
+func (r *RPCTestGeneric) Normalize(result interface{}) interface{} {
+	if r.Normalizer == nil {
+		return NormalizeDropFields(defaultVolatileFields...)(result)
+	}
+	return r.Normalizer(result)
+}
+func (r *RPCTestGeneric) GetArgTypes() []ArgKind {
+	return r.ArgTypes
+}
+func (r *RPCTestGeneric) Setup(rpcClient *rpc.Client) error {
+	return nil
+}
+func (r *RPCTestGeneric) Teardown(rpcClient *rpc.Client) error {
+	return nil
+}
+
+type (
+	// RPCTestPendingTx signs a transaction during Setup (recording
+	// the nonce that was used in pendingTxState), passes it as the
+	// argument of the runner's eth_sendRawTransaction call, then
+	// validates the returned transaction hash and records it in
+	// pendingTxState for the rest of the pending-state test group to
+	// reference.
+	RPCTestPendingTx struct {
+		Validator func(result interface{}) error
+		rawTx     string
+	}
+
+	// RPCTestPendingNonce validates that eth_getTransactionCount at
+	// the "pending" tag reflects the nonce consumed by
+	// RPCTestPendingTx, which only shows up once that transaction has
+	// been submitted.
+	RPCTestPendingNonce struct {
+		Validator func(result interface{}) error
+	}
+
+	// RPCTestPendingBlock is used for the pending-tag block reads
+	// (eth_getBlockByNumber and eth_getBlockTransactionCountByNumber)
+	// that need to run after RPCTestPendingTx has submitted its
+	// transaction.
+	RPCTestPendingBlock struct {
+		Method    string
+		Validator func(result interface{}) error
+	}
+
+	// RPCTestPendingTxLookup covers the pending-aware ways of fetching
+	// a single transaction (eth_getTransactionByHash and
+	// eth_getTransactionByBlockNumberAndIndex) using the hash recorded
+	// in pendingTxState.
+	RPCTestPendingTxLookup struct {
+		Method    string
+		Validator func(result interface{}) error
+	}
+)
+
+func (r *RPCTestPendingTx) GetMethod() string {
+	return "eth_sendRawTransaction"
+}
+func (r *RPCTestPendingTx) GetArgs() []interface{} {
+	return []interface{}{r.rawTx}
+}
+func (r *RPCTestPendingTx) Validate(result interface{}) error {
+	if err := r.Validator(result); err != nil {
+		return err
+	}
+	resultStr, isValid := result.(string)
+	if !isValid {
+		return fmt.Errorf("Invalid result type. Expected string but got %T", result)
+	}
+	pendingTxState.txHash = ethcommon.HexToHash(resultStr)
+	return nil
+}
+func (r *RPCTestPendingTx) ExpectError() bool {
+	return false
+}
+func (r *RPCTestPendingTx) GetNamespace() string {
+	return namespaceFromMethod(r.GetMethod())
+}
+func (r *RPCTestPendingTx) Normalize(result interface{}) interface{} {
+	return result
+}
+func (r *RPCTestPendingTx) GetArgTypes() []ArgKind {
+	return nil
+}
+func (r *RPCTestPendingTx) Setup(rpcClient *rpc.Client) error {
+	rawTx, nonce, err := buildSignedTx(rpcClient, &testEthAddress, nil, big.NewInt(1))
+	if err != nil {
+		return err
+	}
+	r.rawTx = rawTx
+	pendingTxState.nonce = nonce
+	return nil
+}
+
+// buildSignedTx builds and signs (but does not submit) a transaction
+// from testPrivateKey, using the connected node to source the pending
+// nonce, gas price, and chain id. to == nil produces a contract
+// creation transaction. It returns the RLP-encoded, 0x-prefixed raw
+// transaction and the nonce that was used.
+func buildSignedTx(rpcClient *rpc.Client, to *ethcommon.Address, data []byte, value *big.Int) (string, uint64, error) {
+	var nonceHex string
+	err := rpcClient.Call(&nonceHex, "eth_getTransactionCount", testEthAddress.String(), "pending")
+	if err != nil {
+		return "", 0, fmt.Errorf("Unable to fetch pending nonce for test setup: %w", err)
+	}
+	nonce, err := hexutil.DecodeUint64(nonceHex)
+	if err != nil {
+		return "", 0, fmt.Errorf("Unable to decode pending nonce %s: %w", nonceHex, err)
+	}
+
+	var gasPriceHex string
+	if err = rpcClient.Call(&gasPriceHex, "eth_gasPrice"); err != nil {
+		return "", 0, fmt.Errorf("Unable to fetch gas price for test setup: %w", err)
+	}
+	gasPrice, err := hexutil.DecodeBig(gasPriceHex)
+	if err != nil {
+		return "", 0, fmt.Errorf("Unable to decode gas price %s: %w", gasPriceHex, err)
+	}
+
+	var tx *ethtypes.Transaction
+	if to == nil {
+		tx = ethtypes.NewContractCreation(nonce, value, 3000000, gasPrice, data)
+	} else {
+		tx = ethtypes.NewTransaction(nonce, *to, value, 21000, gasPrice, data)
+	}
+
+	var chainIDHex string
+	if err = rpcClient.Call(&chainIDHex, "eth_chainId"); err != nil {
+		return "", 0, fmt.Errorf("Unable to fetch chain id for test setup: %w", err)
+	}
+	chainID, err := hexutil.DecodeBig(chainIDHex)
+	if err != nil {
+		return "", 0, fmt.Errorf("Unable to decode chain id %s: %w", chainIDHex, err)
+	}
+
+	signedTx, err := ethtypes.SignTx(tx, ethtypes.NewEIP155Signer(chainID), testPrivateKey)
+	if err != nil {
+		return "", 0, fmt.Errorf("Unable to sign tx for test setup: %w", err)
+	}
+
+	rawTxBytes, err := signedTx.MarshalBinary()
+	if err != nil {
+		return "", 0, fmt.Errorf("Unable to encode tx for test setup: %w", err)
+	}
+
+	return hexutil.Encode(rawTxBytes), nonce, nil
+}
+
+// sendSignedTx submits a raw transaction previously built by
+// buildSignedTx and returns its hash.
+func sendSignedTx(rpcClient *rpc.Client, rawTx string) (ethcommon.Hash, error) {
+	var txHashHex string
+	if err := rpcClient.Call(&txHashHex, "eth_sendRawTransaction", rawTx); err != nil {
+		return ethcommon.Hash{}, fmt.Errorf("Unable to send tx for test setup: %w", err)
+	}
+	return ethcommon.HexToHash(txHashHex), nil
+}
+func (r *RPCTestPendingTx) Teardown(rpcClient *rpc.Client) error {
+	return nil
+}
+
+func (r *RPCTestPendingNonce) GetMethod() string {
+	return "eth_getTransactionCount"
+}
+func (r *RPCTestPendingNonce) GetArgs() []interface{} {
+	return []interface{}{testEthAddress.String(), "pending"}
+}
+func (r *RPCTestPendingNonce) Validate(result interface{}) error {
+	if err := r.Validator(result); err != nil {
+		return err
+	}
+	resultStr, isValid := result.(string)
+	if !isValid {
+		return fmt.Errorf("Invalid result type. Expected string but got %T", result)
+	}
+	nonce, err := hexutil.DecodeUint64(resultStr)
+	if err != nil {
+		return fmt.Errorf("Unable to decode pending nonce %s: %w", resultStr, err)
+	}
+	// pendingTxState.nonce is the nonce RPCTestPendingTx's transaction
+	// consumed, so the pending nonce should now be one past it.
+	if want := pendingTxState.nonce + 1; nonce != want {
+		return fmt.Errorf("Expected pending nonce %#x (one past the nonce the pending tx used) but got %#x", want, nonce)
+	}
+	return nil
+}
+func (r *RPCTestPendingNonce) ExpectError() bool {
+	return false
+}
+func (r *RPCTestPendingNonce) GetNamespace() string {
+	return namespaceFromMethod(r.GetMethod())
+}
+func (r *RPCTestPendingNonce) Normalize(result interface{}) interface{} {
+	return result
+}
+func (r *RPCTestPendingNonce) GetArgTypes() []ArgKind {
+	return nil
+}
+func (r *RPCTestPendingNonce) Setup(rpcClient *rpc.Client) error {
+	if pendingTxState.txHash == (ethcommon.Hash{}) {
+		return fmt.Errorf("No pending transaction available; the eth_sendRawTransaction pending test must run first")
+	}
+	return nil
+}
+func (r *RPCTestPendingNonce) Teardown(rpcClient *rpc.Client) error {
+	return nil
+}
+
+func (r *RPCTestPendingBlock) GetMethod() string {
+	return r.Method
+}
+func (r *RPCTestPendingBlock) GetArgs() []interface{} {
+	if r.Method == "eth_getBlockByNumber" {
+		// Ask for full transaction objects so Validate can confirm
+		// the pending tx is actually in this block's list, not just
+		// that the block itself decodes.
+		return []interface{}{"pending", true}
+	}
+	return []interface{}{"pending"}
+}
+func (r *RPCTestPendingBlock) Validate(result interface{}) error {
+	if err := r.Validator(result); err != nil {
+		return err
+	}
+
+	switch r.Method {
+	case "eth_getBlockByNumber":
+		return validatePendingBlockContainsTx(result, pendingTxState.txHash)
+	case "eth_getBlockTransactionCountByNumber":
+		resultStr, isValid := result.(string)
+		if !isValid {
+			return fmt.Errorf("Invalid result type. Expected string but got %T", result)
+		}
+		count, err := hexutil.DecodeUint64(resultStr)
+		if err != nil {
+			return fmt.Errorf("Unable to decode pending transaction count %s: %w", resultStr, err)
+		}
+		if count == 0 {
+			return fmt.Errorf("Expected at least one transaction in the pending block, got 0")
+		}
+	}
+	return nil
+}
+
+// validatePendingBlockContainsTx checks that block is actually the
+// unmined pending block (hash/number null) and that wantTxHash appears
+// among its transactions, proving the "pending" tag reflects the
+// submitted transaction rather than just happening to decode.
+func validatePendingBlockContainsTx(block interface{}, wantTxHash ethcommon.Hash) error {
+	blockMap, isValid := block.(map[string]interface{})
+	if !isValid {
+		return fmt.Errorf("Invalid result type. Expected object but got %T", block)
+	}
+	if blockMap["hash"] != nil || blockMap["number"] != nil {
+		return fmt.Errorf("Expected the pending block to have a null hash/number, got hash=%v number=%v", blockMap["hash"], blockMap["number"])
+	}
+
+	txs, isValid := blockMap["transactions"].([]interface{})
+	if !isValid {
+		return fmt.Errorf("Invalid transactions field type. Expected array but got %T", blockMap["transactions"])
+	}
+	for _, txRaw := range txs {
+		tx, isValid := txRaw.(map[string]interface{})
+		if !isValid {
+			continue
+		}
+		if hashStr, _ := tx["hash"].(string); strings.EqualFold(hashStr, wantTxHash.String()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("Pending tx %s not found in the pending block's transaction list", wantTxHash)
+}
+func (r *RPCTestPendingBlock) ExpectError() bool {
+	return false
+}
+func (r *RPCTestPendingBlock) GetNamespace() string {
+	return namespaceFromMethod(r.GetMethod())
+}
+func (r *RPCTestPendingBlock) Normalize(result interface{}) interface{} {
+	return NormalizeDropFields(defaultVolatileFields...)(result)
+}
+func (r *RPCTestPendingBlock) GetArgTypes() []ArgKind {
+	return nil
+}
+func (r *RPCTestPendingBlock) Setup(rpcClient *rpc.Client) error {
+	if pendingTxState.txHash == (ethcommon.Hash{}) {
+		return fmt.Errorf("No pending transaction available; the eth_sendRawTransaction pending test must run first")
+	}
+	return nil
+}
+func (r *RPCTestPendingBlock) Teardown(rpcClient *rpc.Client) error {
+	return nil
+}
+
+func (r *RPCTestPendingTxLookup) GetMethod() string {
+	return r.Method
+}
+func (r *RPCTestPendingTxLookup) GetArgs() []interface{} {
+	if r.Method == "eth_getTransactionByBlockNumberAndIndex" {
+		return []interface{}{"pending", "0x0"}
+	}
+	return []interface{}{pendingTxState.txHash.String()}
+}
+func (r *RPCTestPendingTxLookup) Validate(result interface{}) error {
+	if err := r.Validator(result); err != nil {
+		return err
+	}
+	tx, isValid := result.(map[string]interface{})
+	if !isValid {
+		return fmt.Errorf("Invalid result type. Expected object but got %T", result)
+	}
+	if hashStr, _ := tx["hash"].(string); !strings.EqualFold(hashStr, pendingTxState.txHash.String()) {
+		return fmt.Errorf("Expected tx hash %s but got %v", pendingTxState.txHash, tx["hash"])
+	}
+	if tx["blockHash"] != nil || tx["blockNumber"] != nil {
+		return fmt.Errorf("Expected an unmined pending tx but got blockHash=%v blockNumber=%v", tx["blockHash"], tx["blockNumber"])
+	}
+	return nil
+}
+func (r *RPCTestPendingTxLookup) ExpectError() bool {
+	return false
+}
+func (r *RPCTestPendingTxLookup) GetNamespace() string {
+	return namespaceFromMethod(r.GetMethod())
+}
+func (r *RPCTestPendingTxLookup) Normalize(result interface{}) interface{} {
+	return NormalizeDropFields(defaultVolatileFields...)(result)
+}
+func (r *RPCTestPendingTxLookup) GetArgTypes() []ArgKind {
+	return nil
+}
+func (r *RPCTestPendingTxLookup) Setup(rpcClient *rpc.Client) error {
+	if pendingTxState.txHash == (ethcommon.Hash{}) {
+		return fmt.Errorf("No pending transaction available; the eth_sendRawTransaction pending test must run first")
+	}
+	return nil
+}
+func (r *RPCTestPendingTxLookup) Teardown(rpcClient *rpc.Client) error {
+	return nil
+}
 
 var RPCFuzzCmd = &cobra.Command{
 	Use:   "rpcfuzz http://localhost:8545",
@@ -402,6 +868,11 @@ endpoint. The idea is to be able to check for various features and
 function to see if the RPC generally conforms to typical geth
 standards for the RPC
 
+The endpoint can be given as http(s)://, ws(s)://, or an ipc:// / .ipc
+path. The subscription tests need a transport that supports server
+push (ws/wss/ipc) and are skipped automatically when run against a
+plain http(s) endpoint; the filter tests run over any transport.
+
 Some setup might be neede depending on how you're testing. We'll
 demonstrate with geth. In order to quickly test this, you can run geth
 in dev mode:
@@ -442,33 +913,92 @@ Once this has been completed this will be the address of the contract:
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cxt := cmd.Context()
-		rpcClient, err := rpc.DialContext(cxt, args[0])
+		rpcClient, err := rpc.DialContext(cxt, normalizeDialURL(args[0]))
 		if err != nil {
 			return err
 		}
+		currentRPCURL = args[0]
+
+		var referenceClient *rpc.Client
+		if *referenceURL != "" {
+			referenceClient, err = rpc.DialContext(cxt, normalizeDialURL(*referenceURL))
+			if err != nil {
+				return fmt.Errorf("Unable to dial reference url %s: %w", *referenceURL, err)
+			}
+		}
+
 		log.Trace().Msg("Doing test setup")
 		setupTests()
 
-		for _, t := range allTests {
-			log.Trace().Str("method", t.GetMethod()).Msg("Running Test")
+		selectedTests, err := selectTests(allTests, *testNamespaces, *testMethods, *testExclude)
+		if err != nil {
+			return err
+		}
+
+		suite := newTestSuite()
+		for _, t := range selectedTests {
+			log.Trace().Str("method", t.GetMethod()).Str("namespace", t.GetNamespace()).Msg("Running Test")
+			start := time.Now()
+			if err = t.Setup(rpcClient); err != nil {
+				log.Error().Err(err).Str("method", t.GetMethod()).Msg("Test setup failed")
+				suite.record(t, testStatusSkip, time.Since(start), err)
+				continue
+			}
+
 			var result interface{}
 			err = rpcClient.CallContext(cxt, &result, t.GetMethod(), t.GetArgs()...)
 			if err != nil && !t.ExpectError() {
 				log.Error().Err(err).Str("method", t.GetMethod()).Msg("Method test failed")
+				_ = t.Teardown(rpcClient)
+				suite.record(t, testStatusFail, time.Since(start), err)
 				continue
 			}
 
+			// validated is whichever of the call's outcomes this test
+			// actually cares about: the error for an ExpectError
+			// test, the decoded result otherwise. It's fed to both
+			// Validate and the reference comparator so an ExpectError
+			// test's diff is computed against the real error instead
+			// of the empty result CallContext left behind.
+			var validated interface{}
 			if t.ExpectError() {
-				err = t.Validate(err)
+				validated = err
 			} else {
-				err = t.Validate(result)
+				validated = result
+			}
+			err = t.Validate(validated)
+
+			referenceDiff := ""
+			if referenceClient != nil {
+				referenceDiff = diffAgainstReference(cxt, referenceClient, t, validated)
+			}
+
+			if tderr := t.Teardown(rpcClient); tderr != nil {
+				log.Error().Err(tderr).Str("method", t.GetMethod()).Msg("Test teardown failed")
 			}
 
 			if err != nil {
 				log.Error().Err(err).Str("method", t.GetMethod()).Msg("Failed to validate")
+				suite.recordWithDiff(t, testStatusFail, time.Since(start), err, referenceDiff)
 				continue
 			}
 			log.Info().Str("method", t.GetMethod()).Msg("Successfully validated")
+			if referenceDiff != "" {
+				log.Warn().Str("method", t.GetMethod()).Str("diff", referenceDiff).Msg("Response differs from reference node")
+			}
+			suite.recordWithDiff(t, testStatusPass, time.Since(start), nil, referenceDiff)
+		}
+
+		if *reportFormat != "" {
+			if err = writeReport(suite, *reportFormat, *reportFile); err != nil {
+				return fmt.Errorf("Unable to write test report: %w", err)
+			}
+		}
+
+		if *fuzzEnabled {
+			if err = runFuzzMode(cxt, rpcClient, selectedTests, *fuzzSeed, *fuzzIterations, *fuzzCorpusFile); err != nil {
+				return fmt.Errorf("Fuzz mode failed: %w", err)
+			}
 		}
 		return nil
 	},
@@ -500,5 +1030,16 @@ func init() {
 	flagSet := RPCFuzzCmd.PersistentFlags()
 	testPrivateHexKey = flagSet.String("private-key", codeQualityPrivateKey, "The hex encoded private key that we'll use to sending transactions")
 	testContractAddress = flagSet.String("contract-address", "0x6fda56c57b0acadb96ed5624ac500c0429d59429", "The address of a contract that can be used for testing")
+	testNamespaces = flagSet.StringSlice("namespaces", []string{}, "A comma separated list of RPC namespaces to run (e.g. eth,net,web3). If unset, all namespaces are run")
+	testMethods = flagSet.StringSlice("methods", []string{}, "A comma separated list of specific RPC methods to run. If unset, all methods in the selected namespaces are run")
+	testExclude = flagSet.StringSlice("exclude", []string{}, "A comma separated list of namespaces or methods to exclude from the run")
+	reportFormat = flagSet.String("report-format", "", "Write a structured test summary in this format: json or junit. If unset, no report is written")
+	reportFile = flagSet.String("report-file", "", "The file to write the structured test summary to. Defaults to stdout")
+	referenceURL = flagSet.String("reference-url", "", "A second json rpc endpoint to run every test against, diffing its (normalized) responses against the primary endpoint. Useful for validating one client against another as ground truth")
+	fuzzEnabled = flagSet.Bool("fuzz", false, "After the conformance suite runs, mutate each fuzzable test's arguments and look for 5xx/panic-shaped errors")
+	fuzzIterations = flagSet.Int("fuzz-iterations", 100, "How many mutated calls to make per fuzzable test when --fuzz is set")
+	fuzzSeed = flagSet.Int64("seed", 0, "Seed for the --fuzz argument mutator, for reproducible runs. 0 picks a random seed and prints it")
+	fuzzCorpusFile = flagSet.String("fuzz-corpus-file", "rpcfuzz-corpus.json", "Where to write the corpus of mutated inputs that produced a finding")
+	openrpcSource = flagSet.String("openrpc", "", "A URL or local file path to an OpenRPC document. Every method it describes that isn't already covered by a hand-written test, and that has an example for each param and a result schema, is auto-registered as a baseline conformance test. Useful for chain-specific extensions such as Bor's bor_ namespace")
 
 }
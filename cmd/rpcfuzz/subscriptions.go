@@ -0,0 +1,167 @@
+package rpcfuzz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/maticnetwork/polygon-cli/rpctypes"
+	"github.com/rs/zerolog/log"
+)
+
+// subscriptionTestLogEmitterBytecode is a minimal contract-creation
+// init code (PUSH1 0, PUSH1 0, LOG0, STOP) that emits a single
+// anonymous, data-less log during its own deployment. It exists
+// purely so the "logs" subscription test can cause its own event
+// instead of hoping one shows up on an otherwise quiet node.
+const subscriptionTestLogEmitterBytecode = "0x60006000a000"
+
+// RPCTestSubscription opens an eth_subscribe channel in Setup and
+// waits for ExpectedCount notifications (each checked against
+// SchemaValidator) within Timeout. GetMethod/GetArgs return a harmless
+// placeholder call (eth_blockNumber) since the real work happens over
+// the subscription channel rather than through the runner's normal
+// request/response call; that placeholder call is what the runner
+// logs against and what ExpectError/Validate are wired up to.
+type RPCTestSubscription struct {
+	SubscriptionName string
+	SubscriptionArgs []interface{}
+	ExpectedCount    int
+	Timeout          time.Duration
+	SchemaValidator  func(result interface{}) error
+
+	// Trigger, if set, runs immediately after the subscription channel
+	// is open so the notification(s) Validate waits for are caused by
+	// this test itself rather than relying on ambient chain activity
+	// to produce one within Timeout.
+	Trigger func(rpcClient *rpc.Client) error
+
+	channel chan json.RawMessage
+	sub     *rpc.ClientSubscription
+}
+
+func (r *RPCTestSubscription) GetMethod() string {
+	return "eth_blockNumber"
+}
+func (r *RPCTestSubscription) GetArgs() []interface{} {
+	return []interface{}{}
+}
+func (r *RPCTestSubscription) ExpectError() bool {
+	return false
+}
+func (r *RPCTestSubscription) GetNamespace() string {
+	return "eth"
+}
+func (r *RPCTestSubscription) Normalize(result interface{}) interface{} {
+	return result
+}
+func (r *RPCTestSubscription) GetArgTypes() []ArgKind {
+	return nil
+}
+func (r *RPCTestSubscription) Setup(rpcClient *rpc.Client) error {
+	if !isNotifyCapable(currentRPCURL) {
+		return fmt.Errorf("Endpoint %s does not support subscriptions; use ws(s):// or ipc://", currentRPCURL)
+	}
+
+	r.channel = make(chan json.RawMessage, r.ExpectedCount+1)
+	subscribeArgs := append([]interface{}{r.SubscriptionName}, r.SubscriptionArgs...)
+	sub, err := rpcClient.EthSubscribe(context.Background(), r.channel, subscribeArgs...)
+	if err != nil {
+		return fmt.Errorf("Unable to subscribe to %s: %w", r.SubscriptionName, err)
+	}
+	r.sub = sub
+
+	if r.Trigger != nil {
+		if err := r.Trigger(rpcClient); err != nil {
+			r.sub.Unsubscribe()
+			return fmt.Errorf("Unable to trigger an event for subscription %s: %w", r.SubscriptionName, err)
+		}
+	}
+	return nil
+}
+
+// triggerLogEvent deploys subscriptionTestLogEmitterBytecode so the
+// "logs" subscription test has a log it caused itself to wait for.
+func triggerLogEvent(rpcClient *rpc.Client) error {
+	rawTx, _, err := buildSignedTx(rpcClient, nil, ethcommon.FromHex(subscriptionTestLogEmitterBytecode), big.NewInt(0))
+	if err != nil {
+		return err
+	}
+	_, err = sendSignedTx(rpcClient, rawTx)
+	return err
+}
+
+// triggerPendingTxEvent submits a harmless value transfer so the
+// "newPendingTransactions" subscription test has a transaction it
+// caused itself to wait for.
+func triggerPendingTxEvent(rpcClient *rpc.Client) error {
+	rawTx, _, err := buildSignedTx(rpcClient, &testEthAddress, nil, big.NewInt(1))
+	if err != nil {
+		return err
+	}
+	_, err = sendSignedTx(rpcClient, rawTx)
+	return err
+}
+func (r *RPCTestSubscription) Teardown(rpcClient *rpc.Client) error {
+	if r.sub != nil {
+		r.sub.Unsubscribe()
+	}
+	return nil
+}
+func (r *RPCTestSubscription) Validate(result interface{}) error {
+	received := 0
+	deadline := time.After(r.Timeout)
+	for received < r.ExpectedCount {
+		select {
+		case notification := <-r.channel:
+			var raw interface{}
+			if err := json.Unmarshal(notification, &raw); err != nil {
+				return fmt.Errorf("Unable to unmarshal %s notification: %w", r.SubscriptionName, err)
+			}
+			if err := r.SchemaValidator(raw); err != nil {
+				return fmt.Errorf("Notification %d for %s failed validation: %w", received, r.SubscriptionName, err)
+			}
+			received++
+		case err := <-r.sub.Err():
+			return fmt.Errorf("Subscription %s ended early: %w", r.SubscriptionName, err)
+		case <-deadline:
+			return fmt.Errorf("Only received %d/%d notifications for %s within %s", received, r.ExpectedCount, r.SubscriptionName, r.Timeout)
+		}
+	}
+	return nil
+}
+
+// setupSubscriptionTests registers eth_subscribe tests for newHeads,
+// logs, and newPendingTransactions. These are skipped (via Setup
+// returning an error) when the configured endpoint isn't ws/wss/ipc.
+func setupSubscriptionTests() {
+	log.Trace().Msg("Registering subscription tests")
+
+	allTests = append(allTests, &RPCTestSubscription{
+		SubscriptionName: "newHeads",
+		ExpectedCount:    1,
+		Timeout:          30 * time.Second,
+		SchemaValidator:  ValidateJSONSchema(rpctypes.RPCSchemaEthBlock),
+	})
+
+	allTests = append(allTests, &RPCTestSubscription{
+		SubscriptionName: "logs",
+		SubscriptionArgs: []interface{}{map[string]interface{}{}},
+		ExpectedCount:    1,
+		Timeout:          30 * time.Second,
+		SchemaValidator:  ValidateJSONSchema(rpctypes.RPCSchemaLog),
+		Trigger:          triggerLogEvent,
+	})
+
+	allTests = append(allTests, &RPCTestSubscription{
+		SubscriptionName: "newPendingTransactions",
+		ExpectedCount:    1,
+		Timeout:          30 * time.Second,
+		SchemaValidator:  ValidateRegexString(`^0x[[:xdigit:]]{64}$`),
+		Trigger:          triggerPendingTxEvent,
+	})
+}
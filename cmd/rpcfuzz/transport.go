@@ -0,0 +1,30 @@
+package rpcfuzz
+
+import "strings"
+
+// isNotifyCapable returns true if rawURL uses a transport that
+// supports server-initiated push (filter polling still works over
+// plain http, but eth_subscribe only delivers notifications over
+// ws/wss/ipc). rpc.DialContext already dispatches to the right
+// transport based on the url's scheme, so this is only used to decide
+// whether the subscription tests can run at all.
+func isNotifyCapable(rawURL string) bool {
+	switch {
+	case strings.HasPrefix(rawURL, "ws://"), strings.HasPrefix(rawURL, "wss://"):
+		return true
+	case strings.HasPrefix(rawURL, "ipc://"), strings.HasSuffix(rawURL, ".ipc"):
+		return true
+	default:
+		return false
+	}
+}
+
+// normalizeDialURL strips an ipc:// scheme down to the bare file path
+// rpc.DialContext expects. go-ethereum's dialer only recognizes
+// http(s)/ws(s) schemes and an empty scheme (treated as a raw IPC
+// path) - an explicit ipc:// scheme hits its default case and fails
+// with "no known transport for URL scheme". This keeps ipc:// usable
+// as the first-class form documented in this command's help text.
+func normalizeDialURL(rawURL string) string {
+	return strings.TrimPrefix(rawURL, "ipc://")
+}
@@ -0,0 +1,107 @@
+package rpctypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type (
+	// OpenRPCDocument is the subset of the OpenRPC specification
+	// (https://spec.open-rpc.org) that rpcfuzz's test auto-registration
+	// cares about: the method list, each method's example params, and
+	// each method's result schema.
+	OpenRPCDocument struct {
+		OpenRPC string          `json:"openrpc"`
+		Methods []OpenRPCMethod `json:"methods"`
+	}
+
+	// OpenRPCMethod describes one JSON-RPC method entry in an OpenRPC
+	// document.
+	OpenRPCMethod struct {
+		Name   string                     `json:"name"`
+		Params []OpenRPCContentDescriptor `json:"params"`
+		Result OpenRPCContentDescriptor   `json:"result"`
+	}
+
+	// OpenRPCContentDescriptor is an OpenRPC "Content Descriptor
+	// Object": a named value with a JSON schema and, optionally, one
+	// or more examples.
+	OpenRPCContentDescriptor struct {
+		Name     string          `json:"name"`
+		Schema   json.RawMessage `json:"schema"`
+		Examples []struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"examples"`
+	}
+)
+
+// LoadOpenRPCSpec reads an OpenRPC document from source, which may be
+// an http(s) URL or a local file path. It's used to populate
+// --openrpc in rpcfuzz, so chain forks that extend the standard
+// execution-apis spec (e.g. Bor's bor_ namespace) can be validated
+// against their own document instead of the upstream one.
+func LoadOpenRPCSpec(source string) (*OpenRPCDocument, error) {
+	var raw []byte
+	var err error
+
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		raw, err = fetchOpenRPCSpec(source)
+	} else {
+		raw, err = ioutil.ReadFile(source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read OpenRPC spec from %s: %w", source, err)
+	}
+
+	doc := new(OpenRPCDocument)
+	if err := json.Unmarshal(raw, doc); err != nil {
+		return nil, fmt.Errorf("Unable to parse OpenRPC spec from %s: %w", source, err)
+	}
+	return doc, nil
+}
+
+func fetchOpenRPCSpec(url string) ([]byte, error) {
+	client := http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unexpected status %s fetching %s", resp.Status, url)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// ExampleParams returns the first example value given for each of the
+// method's params, in order. A method with any param that has no
+// example (or whose first example doesn't decode) returns ok=false
+// since there's nothing reasonable to call it with.
+func (m OpenRPCMethod) ExampleParams() (params []interface{}, ok bool) {
+	params = make([]interface{}, 0, len(m.Params))
+	for _, p := range m.Params {
+		if len(p.Examples) == 0 {
+			return nil, false
+		}
+		var value interface{}
+		if err := json.Unmarshal(p.Examples[0].Value, &value); err != nil {
+			return nil, false
+		}
+		params = append(params, value)
+	}
+	return params, true
+}
+
+// ResultSchema renders the method's result schema back out as a JSON
+// document, suitable for rpcfuzz.ValidateJSONSchema. A method with no
+// result schema returns ok=false.
+func (m OpenRPCMethod) ResultSchema() (schema string, ok bool) {
+	if len(m.Result.Schema) == 0 {
+		return "", false
+	}
+	return string(m.Result.Schema), true
+}
@@ -0,0 +1,135 @@
+package rpctypes
+
+// RPCSchemaStructLogs matches debug_traceTransaction's response when no
+// tracer is specified: the default struct logger's
+// {gas, failed, returnValue, structLogs} shape.
+const RPCSchemaStructLogs = `{
+	"type": "object",
+	"properties": {
+		"gas": {"type": "integer"},
+		"failed": {"type": "boolean"},
+		"returnValue": {"type": "string"},
+		"structLogs": {"type": "array"}
+	},
+	"required": ["gas", "failed", "structLogs"]
+}`
+
+// RPCSchemaCallFrame matches the result of debug_trace* calls made
+// with callTracer: a recursive call frame with the top-level call's
+// type/from/to and its nested calls.
+const RPCSchemaCallFrame = `{
+	"type": "object",
+	"properties": {
+		"type": {"type": "string"},
+		"from": {"type": "string"},
+		"to": {"type": "string"},
+		"gas": {"type": "string"},
+		"gasUsed": {"type": "string"},
+		"input": {"type": "string"},
+		"output": {"type": "string"},
+		"calls": {"type": "array"}
+	},
+	"required": ["type", "from"]
+}`
+
+// RPCSchemaPrestate matches the result of debug_traceTransaction made
+// with prestateTracer: a map of address to that address's pre-call
+// account state.
+const RPCSchemaPrestate = `{
+	"type": "object",
+	"patternProperties": {
+		"^0x[0-9a-fA-F]{40}$": {
+			"type": "object",
+			"properties": {
+				"balance": {"type": "string"},
+				"nonce": {"type": "integer"},
+				"code": {"type": "string"},
+				"storage": {"type": "object"}
+			}
+		}
+	}
+}`
+
+// RPCSchemaBlockTraceArray matches debug_traceBlockByNumber and
+// debug_traceBlockByHash's response when called with the default,
+// tracer-less config: one {result, txHash} entry per transaction in
+// the block, with result in the same shape as
+// debug_traceTransaction's default struct logger output.
+const RPCSchemaBlockTraceArray = `{
+	"type": "array",
+	"items": {
+		"type": "object",
+		"properties": {
+			"txHash": {"type": "string"},
+			"result": {
+				"type": "object",
+				"properties": {
+					"gas": {"type": "integer"},
+					"failed": {"type": "boolean"},
+					"returnValue": {"type": "string"},
+					"structLogs": {"type": "array"}
+				},
+				"required": ["gas", "failed", "structLogs"]
+			}
+		},
+		"required": ["result"]
+	}
+}`
+
+// RPCSchemaStringArray matches any RPC result that's a flat array of
+// strings, e.g. a custom JS tracer that accumulates opcode names.
+const RPCSchemaStringArray = `{
+	"type": "array",
+	"items": {"type": "string"}
+}`
+
+// RPCSchemaLog matches a single log object, the shape delivered one
+// at a time by an eth_subscribe("logs") notification.
+const RPCSchemaLog = `{
+	"type": "object",
+	"properties": {
+		"address": {"type": "string"},
+		"topics": {"type": "array"},
+		"data": {"type": "string"},
+		"blockNumber": {"type": ["string", "null"]},
+		"transactionHash": {"type": "string"},
+		"logIndex": {"type": ["string", "null"]}
+	},
+	"required": ["address", "topics", "data"]
+}`
+
+// RPCSchemaLogArray matches eth_getLogs and eth_getFilterLogs: an
+// array of log objects.
+const RPCSchemaLogArray = `{
+	"type": "array",
+	"items": {
+		"type": "object",
+		"properties": {
+			"address": {"type": "string"},
+			"topics": {"type": "array"},
+			"data": {"type": "string"},
+			"blockNumber": {"type": ["string", "null"]},
+			"transactionHash": {"type": "string"},
+			"logIndex": {"type": ["string", "null"]}
+		},
+		"required": ["address", "topics", "data"]
+	}
+}`
+
+// RPCSchemaEthTransaction matches the object returned by
+// eth_getTransactionByHash and
+// eth_getTransactionByBlockNumberAndIndex: a single transaction,
+// possibly still pending (blockHash/blockNumber null).
+const RPCSchemaEthTransaction = `{
+	"type": "object",
+	"properties": {
+		"hash": {"type": "string"},
+		"nonce": {"type": "string"},
+		"from": {"type": "string"},
+		"to": {"type": ["string", "null"]},
+		"value": {"type": "string"},
+		"blockHash": {"type": ["string", "null"]},
+		"blockNumber": {"type": ["string", "null"]}
+	},
+	"required": ["hash", "nonce", "from"]
+}`